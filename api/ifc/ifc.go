@@ -0,0 +1,47 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ifc holds the interfaces kv.Loader is built against, so callers
+// can substitute their own file access and validation without depending
+// on the kv package's concrete types.
+package ifc
+
+import (
+	"context"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// Loader reads the raw bytes at path -- a file on disk, a fetched HTTP
+// resource, whatever the caller's Loader implementation knows how to
+// reach -- without knowing anything about KV pairs.
+type Loader interface {
+	Load(path string) ([]byte, error)
+}
+
+// Validator validates the strings a KvLoader resolves into pairs, e.g.
+// rejecting a key that isn't a legal environment variable name.
+type Validator interface {
+	IsEnvVarName(name string) error
+}
+
+// KvLoader turns a types.KvPairSources into resolved types.Pair values,
+// decrypting any age-armored values along the way.
+type KvLoader interface {
+	// Validator returns the Validator pair keys are checked against.
+	Validator() Validator
+
+	// Load resolves every source in args into a types.Pair.
+	Load(args types.KvPairSources) ([]types.Pair, error)
+
+	// Watch behaves like Load, but instead of returning once, it calls
+	// onChange with a freshly Load-ed set of pairs -- or the error Load
+	// returned -- every time one of args' sources changes on disk. It
+	// returns once the watch is established; ctx cancellation stops it.
+	Watch(ctx context.Context, args types.KvPairSources, onChange func([]types.Pair, error)) error
+
+	// Close releases any long-lived connections this KvLoader opened on
+	// its own behalf (e.g. a keyservice gRPC connection, an ssh-agent
+	// socket). Safe to call even if Watch/Load were never called.
+	Close() error
+}
@@ -0,0 +1,44 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// connCache holds the long-lived network connections getAgeIdentities
+// opens on a loader's behalf, keyed so a repeated call -- as Watch makes on
+// every debounced reload -- reuses them instead of redialing (and leaking)
+// on every reload. A one-shot `kustomize build` still just drops these on
+// the floor when the process exits, same as before.
+type connCache struct {
+	mu sync.Mutex
+
+	keyServiceConns map[string]*grpc.ClientConn
+	sshAgentConn    *cachedSSHAgentConn
+}
+
+// Close releases every connection this cache is holding. Safe to call more
+// than once, and safe on a zero-value connCache.
+func (c *connCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for uri, conn := range c.keyServiceConns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.keyServiceConns, uri)
+	}
+	if c.sshAgentConn != nil {
+		if err := c.sshAgentConn.conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		c.sshAgentConn = nil
+	}
+	return firstErr
+}
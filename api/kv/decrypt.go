@@ -0,0 +1,154 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	"gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const defaultUnencryptedKeySuffix = "_unencrypted"
+
+// decryptValueWithAge decrypts ciphertext, which may or may not be
+// ASCII-armored, against ids. It is the inverse of EncryptValueWithAge.
+func decryptValueWithAge(ciphertext []byte, ids []age.Identity) ([]byte, error) {
+	src := bytes.NewReader(ciphertext)
+	var r *age.Reader
+	var err error
+	if bytes.HasPrefix(ciphertext, []byte(armor.Header)) {
+		r, err = age.Decrypt(armor.NewReader(src), ids...)
+	} else {
+		r, err = age.Decrypt(src, ids...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// yamlEncryptionPolicy decides, per mapping key, whether the values beneath
+// it are left alone, decrypted/encrypted, or always left as plaintext.
+// It is built once per KvPairSources and threaded down to
+// decryptInlineYAMLWithAge / EncryptInlineYAMLWithAge so a single source can
+// carry both cleartext and ciphertext.
+type yamlEncryptionPolicy struct {
+	unencryptedSuffix string
+	encryptedRegex    *regexp.Regexp
+}
+
+func newYAMLEncryptionPolicyFromArgs(args types.KvPairSources) (yamlEncryptionPolicy, error) {
+	return newYAMLEncryptionPolicy(args.UnencryptedKeySuffix, args.EncryptedKeyRegex, args.EncryptedKeySuffix)
+}
+
+// newYAMLEncryptionPolicy builds a yamlEncryptionPolicy from the raw
+// settings; unencryptedSuffix defaults to "_unencrypted" and at most one of
+// encryptedRegex/encryptedSuffix should be set.
+func newYAMLEncryptionPolicy(unencryptedSuffix, encryptedRegex, encryptedSuffix string) (yamlEncryptionPolicy, error) {
+	p := yamlEncryptionPolicy{unencryptedSuffix: unencryptedSuffix}
+	if p.unencryptedSuffix == "" {
+		p.unencryptedSuffix = defaultUnencryptedKeySuffix
+	}
+	switch {
+	case encryptedRegex != "":
+		re, err := regexp.Compile(encryptedRegex)
+		if err != nil {
+			return p, err
+		}
+		p.encryptedRegex = re
+	case encryptedSuffix != "":
+		re, err := regexp.Compile(regexp.QuoteMeta(encryptedSuffix) + "$")
+		if err != nil {
+			return p, err
+		}
+		p.encryptedRegex = re
+	}
+	return p, nil
+}
+
+// isUnencryptedKey reports whether key opts a node (and everything beneath
+// it) out of encryption.
+func (p yamlEncryptionPolicy) isUnencryptedKey(key string) bool {
+	return strings.HasSuffix(key, p.unencryptedSuffix)
+}
+
+// encryptsKey reports whether scalars under key should be encrypted. With no
+// encryptedRegex configured every key (other than an unencrypted one)
+// qualifies, preserving the original all-or-nothing behavior.
+func (p yamlEncryptionPolicy) encryptsKey(key string) bool {
+	if p.encryptedRegex == nil {
+		return true
+	}
+	return p.encryptedRegex.MatchString(key)
+}
+
+// decryptInlineYAMLWithAge walks the YAML document in content and decrypts
+// each armored scalar in place, skipping anything beneath an
+// `_unencrypted`-suffixed key (policy.unencryptedSuffix) and anything outside
+// an encrypted_regex/encrypted_suffix key when one is configured.
+func decryptInlineYAMLWithAge(
+	content []byte, ids []age.Identity, policy yamlEncryptionPolicy) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if err := walkInlineYAML(&doc, true, false, policy, func(n *yaml.Node) error {
+		plain, err := decryptValueWithAge([]byte(n.Value), ids)
+		if err != nil {
+			return err
+		}
+		n.Value = string(plain)
+		n.Tag = "!!str"
+		n.Style = 0
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(&doc)
+}
+
+// walkInlineYAML descends n, calling leaf for every scalar value whose
+// governing mapping key currently qualifies for the operation, per policy.
+// active starts true (document root); once a node falls under an
+// `_unencrypted` key it stays false for the rest of its subtree regardless
+// of what encryptedRegex says. matched starts false and, symmetrically,
+// once a node falls under a key encryptsKey matches it stays true for the
+// rest of its subtree -- an encrypted_regex/encrypted_suffix match is
+// sticky, not something every intermediate key on the way down must
+// individually re-satisfy, so e.g. `encrypted_regex: '^secret_data'` over
+// `secret_data: {meta: {value: ...}}` still reaches `value` even though
+// `meta` itself doesn't match.
+func walkInlineYAML(n *yaml.Node, active, matched bool, policy yamlEncryptionPolicy, leaf func(*yaml.Node) error) error {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			if err := walkInlineYAML(c, active, matched, policy, leaf); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			value := n.Content[i+1]
+			childActive := active && !policy.isUnencryptedKey(key.Value)
+			childMatched := matched || policy.encryptsKey(key.Value)
+			if err := walkInlineYAML(value, childActive, childMatched, policy, leaf); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if active && matched {
+			return leaf(n)
+		}
+	}
+	return nil
+}
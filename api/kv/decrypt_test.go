@@ -0,0 +1,80 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func mustGenerateX25519(t *testing.T) *age.X25519Identity {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("generating test identity: %v", err)
+	}
+	return id
+}
+
+// TestEncryptDecryptInlineYAMLWithAge_NestedRegexMatch exercises the bug
+// fixed in walkInlineYAML: an encrypted_regex match on an ancestor key must
+// be sticky for everything beneath it, not re-evaluated (and thereby
+// defeated) at every nesting level.
+func TestEncryptDecryptInlineYAMLWithAge_NestedRegexMatch(t *testing.T) {
+	id := mustGenerateX25519(t)
+	recipients := []age.Recipient{id.Recipient()}
+
+	plain := []byte(`
+secret_data:
+  meta:
+    value: hunter2
+plain_data: not-a-secret
+`)
+
+	ciphertext, err := EncryptInlineYAMLWithAge(plain, recipients, "", "^secret_data")
+	if err != nil {
+		t.Fatalf("EncryptInlineYAMLWithAge: %v", err)
+	}
+	if !strings.Contains(string(ciphertext), "not-a-secret") {
+		t.Fatalf("plain_data should be left untouched, got:\n%s", ciphertext)
+	}
+	if strings.Contains(string(ciphertext), "hunter2") {
+		t.Fatalf("nested value under secret_data should have been encrypted, got:\n%s", ciphertext)
+	}
+
+	policy, err := newYAMLEncryptionPolicy("", "^secret_data", "")
+	if err != nil {
+		t.Fatalf("newYAMLEncryptionPolicy: %v", err)
+	}
+	roundTripped, err := decryptInlineYAMLWithAge(ciphertext, []age.Identity{id}, policy)
+	if err != nil {
+		t.Fatalf("decryptInlineYAMLWithAge: %v", err)
+	}
+	if !strings.Contains(string(roundTripped), "hunter2") {
+		t.Fatalf("round trip lost the nested encrypted value, got:\n%s", roundTripped)
+	}
+	if !strings.Contains(string(roundTripped), "not-a-secret") {
+		t.Fatalf("round trip altered the untouched plain value, got:\n%s", roundTripped)
+	}
+}
+
+// TestWalkInlineYAML_UnencryptedStaysOffForSubtree confirms the
+// `_unencrypted` suffix keeps a whole subtree unencrypted regardless of an
+// encrypted_regex match below it, since the two stickiness rules combine.
+func TestWalkInlineYAML_UnencryptedStaysOffForSubtree(t *testing.T) {
+	id := mustGenerateX25519(t)
+	plain := []byte(`
+secret_data_unencrypted:
+  value: plaintext-please
+`)
+	ciphertext, err := EncryptInlineYAMLWithAge(plain, []age.Recipient{id.Recipient()}, "", "^secret_data")
+	if err != nil {
+		t.Fatalf("EncryptInlineYAMLWithAge: %v", err)
+	}
+	if !strings.Contains(string(ciphertext), "plaintext-please") {
+		t.Fatalf("value under an _unencrypted key must not be armored, got:\n%s", ciphertext)
+	}
+}
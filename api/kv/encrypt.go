@@ -0,0 +1,181 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"filippo.io/age/armor"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// EncryptValueWithAge age-armor-encrypts plain for each of recipients. It is
+// the inverse of decryptValueWithAge.
+func EncryptValueWithAge(plain []byte, recipients []age.Recipient) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	aw := armor.NewWriter(buf)
+	w, err := age.Encrypt(aw, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plain); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncryptInlineYAMLWithAge walks the YAML document in plain and
+// age-armor-encrypts its scalar leaf values in place, preserving document
+// structure and comments. unencryptedSuffix and encryptedKeyRegex mirror the
+// `_unencrypted`/encrypted_regex rules honored by decryptInlineYAMLWithAge;
+// pass "" for both to encrypt every scalar not under an `_unencrypted` key
+// (an empty unencryptedSuffix means the default "_unencrypted").
+func EncryptInlineYAMLWithAge(
+	plain []byte, recipients []age.Recipient, unencryptedSuffix, encryptedKeyRegex string) ([]byte, error) {
+	policy, err := newYAMLEncryptionPolicy(unencryptedSuffix, encryptedKeyRegex, "")
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(plain, &doc); err != nil {
+		return nil, err
+	}
+	if err := walkInlineYAML(&doc, true, false, policy, func(n *yaml.Node) error {
+		ciphertext, err := EncryptValueWithAge([]byte(n.Value), recipients)
+		if err != nil {
+			return err
+		}
+		n.Value = string(ciphertext)
+		n.Tag = "!!str"
+		n.Style = yaml.LiteralStyle
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(&doc)
+}
+
+// AgeRecipientsFromKvPairSources resolves args.AgeRecipients the same way
+// AgeRecipientsFromSpecs does. It's the entry point for a caller building
+// a types.KvPairSources up front (e.g. from a kustomization's
+// secretGenerator options) rather than driving CLI flags directly.
+func AgeRecipientsFromKvPairSources(args types.KvPairSources) ([]age.Recipient, error) {
+	return AgeRecipientsFromSpecs(args.AgeRecipients)
+}
+
+// AgeRecipientsFromSpecs loads age recipients from the sources named in
+// specs: paths to `.pub` files, `https://github.com/<user>.keys`
+// URLs (GitHub publishes a user's registered SSH public keys there), and
+// inline `age1...` recipient strings.
+func AgeRecipientsFromSpecs(specs []string) ([]age.Recipient, error) {
+	var recipients []age.Recipient
+	for _, spec := range specs {
+		rs, err := ageRecipientsFromSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("age recipient %q: %w", spec, err)
+		}
+		recipients = append(recipients, rs...)
+	}
+	return recipients, nil
+}
+
+func ageRecipientsFromSpec(spec string) ([]age.Recipient, error) {
+	switch {
+	case strings.HasPrefix(spec, "age1"):
+		r, err := age.ParseX25519Recipient(spec)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{r}, nil
+	case strings.HasPrefix(spec, "https://github.com/") && strings.HasSuffix(spec, ".keys"):
+		return githubSSHRecipients(spec)
+	default:
+		content, err := ioutil.ReadFile(spec)
+		if err != nil {
+			return nil, err
+		}
+		return parseRecipientsFile(content)
+	}
+}
+
+// parseRecipientsFile parses a `.pub` file of one recipient per line: age
+// recipients, ssh-rsa/ssh-ed25519 public keys, or blank/`#` comment lines,
+// which are skipped.
+func parseRecipientsFile(content []byte) ([]age.Recipient, error) {
+	var recipients []age.Recipient
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := parseRecipientLine(line)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, nil
+}
+
+func parseRecipientLine(line string) (age.Recipient, error) {
+	if strings.HasPrefix(line, "age1") {
+		return age.ParseX25519Recipient(line)
+	}
+	return sshRecipient(line)
+}
+
+func sshRecipient(line string) (age.Recipient, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+	return agessh.NewRecipient(pub)
+}
+
+// githubSSHRecipients fetches a GitHub user's registered public SSH keys
+// from https://github.com/<user>.keys and parses the age-compatible ones
+// (ssh-rsa, ssh-ed25519).
+func githubSSHRecipients(url string) ([]age.Recipient, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var recipients []age.Recipient
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "ssh-rsa ") && !strings.HasPrefix(line, "ssh-ed25519 ") {
+			continue
+		}
+		r, err := sshRecipient(line)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, nil
+}
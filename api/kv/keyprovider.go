@@ -0,0 +1,143 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"context"
+	"fmt"
+
+	"filippo.io/age"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// KeyProvider wraps and unwraps the per-file data key used to decrypt (and,
+// where supported, encrypt) an age-armored KV source. It lets kustomize
+// delegate that operation to an external key management system instead of
+// requiring a raw age identity on disk.
+type KeyProvider interface {
+	// Name identifies the provider, e.g. "awskms" or "vaulttransit". It is
+	// recorded as the stanza type in the header of data wrapped by this
+	// provider so a later Load can tell which provider(s) to ask.
+	Name() string
+
+	// Decrypt unwraps dataKey, previously produced by Encrypt (possibly by a
+	// different kustomize invocation, or a different machine entirely), and
+	// returns the underlying file key.
+	Decrypt(ctx context.Context, dataKey []byte) ([]byte, error)
+
+	// Encrypt wraps dataKey for storage alongside the ciphertext.
+	Encrypt(ctx context.Context, dataKey []byte) ([]byte, error)
+}
+
+// keyProviderIdentity adapts a KeyProvider to age.Identity so its wrapped
+// file keys can be unwrapped alongside ordinary age recipient stanzas.
+type keyProviderIdentity struct {
+	ctx context.Context
+	kp  KeyProvider
+}
+
+func (k keyProviderIdentity) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type != k.kp.Name() {
+			continue
+		}
+		fileKey, err := k.kp.Decrypt(k.ctx, []byte(s.Body))
+		if err != nil {
+			continue
+		}
+		return fileKey, nil
+	}
+	return nil, age.ErrIncorrectIdentity
+}
+
+// keyProviderRecipient adapts a KeyProvider to age.Recipient so it can wrap
+// a file key for storage alongside ordinary age recipient stanzas,
+// mirroring keyProviderIdentity on the decrypt side. The stanza it
+// produces is typed kp.Name(), which is how keyProviderIdentity.Unwrap
+// later tells which provider to ask.
+type keyProviderRecipient struct {
+	ctx context.Context
+	kp  KeyProvider
+}
+
+func (k keyProviderRecipient) Wrap(fileKey []byte) ([]*age.Stanza, error) {
+	wrapped, err := k.kp.Encrypt(k.ctx, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return []*age.Stanza{{Type: k.kp.Name(), Body: wrapped}}, nil
+}
+
+// KeyProviderRecipients wraps kps as age recipients, in the context given,
+// for use alongside ordinary age1.../ssh recipients passed to
+// EncryptValueWithAge/EncryptInlineYAMLWithAge. It is the encrypt-side
+// counterpart of keyProviderIdentities.
+func KeyProviderRecipients(ctx context.Context, kps []KeyProvider) []age.Recipient {
+	recipients := make([]age.Recipient, 0, len(kps))
+	for _, kp := range kps {
+		recipients = append(recipients, keyProviderRecipient{ctx: ctx, kp: kp})
+	}
+	return recipients
+}
+
+// KeyProviderRecipientsFromSpecs instantiates the providers named in specs
+// and wraps them as age recipients, for use alongside AgeRecipientsFromSpecs
+// when encrypting. The stanza type each wraps with is the provider's Name(),
+// which is how a later Load (via keyProviderIdentities) knows which
+// provider(s) to ask to unwrap it.
+func KeyProviderRecipientsFromSpecs(ctx context.Context, specs []types.KeyProviderSpec) ([]age.Recipient, error) {
+	kps, err := keyProvidersFromSpecs(specs)
+	if err != nil {
+		return nil, err
+	}
+	return KeyProviderRecipients(ctx, kps), nil
+}
+
+// keyProvidersFromSpecs instantiates the providers selected by specs so that
+// their identities can be added to the age.Identity set used while
+// decrypting.
+func keyProvidersFromSpecs(specs []types.KeyProviderSpec) ([]KeyProvider, error) {
+	var kps []KeyProvider
+	for _, spec := range specs {
+		kp, err := newKeyProvider(spec)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("key provider %q", spec.Name))
+		}
+		kps = append(kps, kp)
+	}
+	return kps, nil
+}
+
+// newKeyProvider builds the built-in KeyProvider named by spec.Type.
+func newKeyProvider(spec types.KeyProviderSpec) (KeyProvider, error) {
+	switch spec.Type {
+	case "age":
+		return newAgeKeyProvider(spec)
+	case "awskms":
+		return newAWSKMSKeyProvider(spec)
+	case "gcpkms":
+		return newGCPKMSKeyProvider(spec)
+	case "azurekeyvault":
+		return newAzureKeyVaultKeyProvider(spec)
+	case "vaulttransit":
+		return newVaultTransitKeyProvider(spec)
+	case "pgp":
+		return newPGPKeyProvider(spec)
+	default:
+		return nil, fmt.Errorf("unknown key provider type %q", spec.Type)
+	}
+}
+
+// keyProviderIdentities wraps kps as age identities, in the context given,
+// for use alongside file-based and ssh-agent identities.
+func keyProviderIdentities(ctx context.Context, kps []KeyProvider) []age.Identity {
+	ids := make([]age.Identity, 0, len(kps))
+	for _, kp := range kps {
+		ids = append(ids, keyProviderIdentity{ctx: ctx, kp: kp})
+	}
+	return ids
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"context"
+
+	"filippo.io/age"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// ageKeyProvider wraps a plain age X25519 identity so it can be selected
+// through the KeyProviders list the same way the cloud/HSM-backed providers
+// are, rather than only via AgeIdentitySources.
+type ageKeyProvider struct {
+	name     string
+	identity *age.X25519Identity
+}
+
+func newAgeKeyProvider(spec types.KeyProviderSpec) (KeyProvider, error) {
+	id, err := age.ParseX25519Identity(spec.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ageKeyProvider{name: spec.Name, identity: id}, nil
+}
+
+func (p *ageKeyProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return "age"
+}
+
+func (p *ageKeyProvider) Decrypt(_ context.Context, dataKey []byte) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(dataKey), p.identity)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *ageKeyProvider) Encrypt(_ context.Context, dataKey []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w, err := age.Encrypt(buf, p.identity.Recipient())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(dataKey); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
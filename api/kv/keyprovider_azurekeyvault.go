@@ -0,0 +1,64 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// azureKeyVaultKeyProvider wraps the per-file data key with an Azure Key
+// Vault key, identified by its vault URL and key name, authenticating via
+// the default Azure credential chain.
+type azureKeyVaultKeyProvider struct {
+	name    string
+	keyName string
+	client  *azkeys.Client
+}
+
+func newAzureKeyVaultKeyProvider(spec types.KeyProviderSpec) (KeyProvider, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, err
+	}
+	client, err := azkeys.NewClient(spec.VaultURL, cred, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &azureKeyVaultKeyProvider{name: spec.Name, keyName: spec.ID, client: client}, nil
+}
+
+func (p *azureKeyVaultKeyProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return "azurekeyvault"
+}
+
+func (p *azureKeyVaultKeyProvider) Decrypt(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := p.client.Decrypt(ctx, p.keyName, "", azkeys.KeyOperationParameters{
+		Algorithm: to.Ptr(azkeys.EncryptionAlgorithmRSAOAEP256),
+		Value:     dataKey,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
+func (p *azureKeyVaultKeyProvider) Encrypt(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := p.client.Encrypt(ctx, p.keyName, "", azkeys.KeyOperationParameters{
+		Algorithm: to.Ptr(azkeys.EncryptionAlgorithmRSAOAEP256),
+		Value:     dataKey,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
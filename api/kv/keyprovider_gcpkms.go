@@ -0,0 +1,59 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"context"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	kmspb "cloud.google.com/go/kms/apiv1/kmspb"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// gcpKMSKeyProvider wraps the per-file data key with a GCP Cloud KMS key,
+// identified by its full resource name
+// (projects/*/locations/*/keyRings/*/cryptoKeys/*).
+type gcpKMSKeyProvider struct {
+	name    string
+	keyName string
+	client  *kms.KeyManagementClient
+}
+
+func newGCPKMSKeyProvider(spec types.KeyProviderSpec) (KeyProvider, error) {
+	client, err := kms.NewKeyManagementClient(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &gcpKMSKeyProvider{name: spec.Name, keyName: spec.ID, client: client}, nil
+}
+
+func (p *gcpKMSKeyProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return "gcpkms"
+}
+
+func (p *gcpKMSKeyProvider) Decrypt(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := p.client.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:       p.keyName,
+		Ciphertext: dataKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+func (p *gcpKMSKeyProvider) Encrypt(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := p.client.Encrypt(ctx, &kmspb.EncryptRequest{
+		Name:      p.keyName,
+		Plaintext: dataKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Ciphertext, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// pgpKeyProvider wraps the per-file data key with an OpenPGP key pair,
+// loaded from the armored keyring file at spec.ID. Decrypt requires the
+// private key (optionally protected by spec.Passphrase); Encrypt only
+// needs the public key.
+type pgpKeyProvider struct {
+	name     string
+	entities openpgp.EntityList
+}
+
+func newPGPKeyProvider(spec types.KeyProviderSpec) (KeyProvider, error) {
+	f, err := ioutil.ReadFile(spec.ID)
+	if err != nil {
+		return nil, err
+	}
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(f))
+	if err != nil {
+		return nil, err
+	}
+	if spec.Passphrase != "" {
+		for _, e := range entities {
+			if e.PrivateKey != nil && e.PrivateKey.Encrypted {
+				if err := e.PrivateKey.Decrypt([]byte(spec.Passphrase)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return &pgpKeyProvider{name: spec.Name, entities: entities}, nil
+}
+
+func (p *pgpKeyProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return "pgp"
+}
+
+func (p *pgpKeyProvider) Decrypt(_ context.Context, dataKey []byte) ([]byte, error) {
+	md, err := openpgp.ReadMessage(bytes.NewReader(dataKey), p.entities, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(md.UnverifiedBody)
+}
+
+func (p *pgpKeyProvider) Encrypt(_ context.Context, dataKey []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w, err := openpgp.Encrypt(buf, p.entities, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(dataKey); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
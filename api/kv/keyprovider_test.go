@@ -0,0 +1,87 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+// fakeKeyProvider is a minimal KeyProvider standing in for a KMS/Vault/PGP
+// backend: it "wraps" a data key by reversing its bytes and prepending a
+// marker, which is enough to prove Encrypt and Decrypt are each other's
+// inverse through the age.Recipient/age.Identity adapters without needing
+// real network calls.
+type fakeKeyProvider struct {
+	name string
+}
+
+const fakeKeyProviderMarker = "fake:"
+
+func (f fakeKeyProvider) Name() string { return f.name }
+
+func (f fakeKeyProvider) Encrypt(_ context.Context, dataKey []byte) ([]byte, error) {
+	reversed := reverseBytes(dataKey)
+	return append([]byte(fakeKeyProviderMarker), reversed...), nil
+}
+
+func (f fakeKeyProvider) Decrypt(_ context.Context, wrapped []byte) ([]byte, error) {
+	reversed := bytes.TrimPrefix(wrapped, []byte(fakeKeyProviderMarker))
+	return reverseBytes(reversed), nil
+}
+
+func reverseBytes(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, c := range b {
+		out[len(b)-1-i] = c
+	}
+	return out
+}
+
+// TestKeyProviderRecipientIdentityRoundTrip proves keyProviderRecipient and
+// keyProviderIdentity are actual inverses of each other: before this test,
+// nothing ever called KeyProvider.Encrypt, so there was no way to produce a
+// file any KeyProvider could decrypt.
+func TestKeyProviderRecipientIdentityRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	kp := fakeKeyProvider{name: "fake-kms"}
+
+	recipients := KeyProviderRecipients(ctx, []KeyProvider{kp})
+	plain := []byte("top secret")
+	ciphertext, err := EncryptValueWithAge(plain, recipients)
+	if err != nil {
+		t.Fatalf("EncryptValueWithAge: %v", err)
+	}
+
+	ids := keyProviderIdentities(ctx, []KeyProvider{kp})
+	decrypted, err := decryptValueWithAge(ciphertext, ids)
+	if err != nil {
+		t.Fatalf("decryptValueWithAge: %v", err)
+	}
+	if !bytes.Equal(decrypted, plain) {
+		t.Fatalf("round trip mismatch: got %q, want %q", decrypted, plain)
+	}
+}
+
+// TestKeyProviderIdentityIgnoresOtherProviderStanzas confirms
+// keyProviderIdentity.Unwrap skips stanzas stamped with a different
+// provider's name instead of mistakenly handing them to the wrong
+// provider's Decrypt.
+func TestKeyProviderIdentityIgnoresOtherProviderStanzas(t *testing.T) {
+	ctx := context.Background()
+	kp := fakeKeyProvider{name: "fake-kms"}
+	other := fakeKeyProvider{name: "other-kms"}
+
+	recipients := KeyProviderRecipients(ctx, []KeyProvider{other})
+	ciphertext, err := EncryptValueWithAge([]byte("hi"), recipients)
+	if err != nil {
+		t.Fatalf("EncryptValueWithAge: %v", err)
+	}
+
+	ids := keyProviderIdentities(ctx, []KeyProvider{kp})
+	if _, err := decryptValueWithAge(ciphertext, ids); err == nil {
+		t.Fatal("expected an error decrypting a stanza stamped for a different provider, got nil")
+	}
+}
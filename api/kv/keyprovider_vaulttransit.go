@@ -0,0 +1,89 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	vault "github.com/hashicorp/vault/api"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// vaultTransitKeyProvider wraps the per-file data key with a HashiCorp Vault
+// Transit key, identified by the mount path and key name in spec.ID
+// ("<mount>/<key>"), authenticating with the ambient VAULT_TOKEN.
+type vaultTransitKeyProvider struct {
+	name      string
+	mountPath string
+	keyName   string
+	client    *vault.Client
+}
+
+func newVaultTransitKeyProvider(spec types.KeyProviderSpec) (KeyProvider, error) {
+	mountPath, keyName, err := splitVaultTransitID(spec.ID)
+	if err != nil {
+		return nil, err
+	}
+	cfg := vault.DefaultConfig()
+	if spec.VaultAddress != "" {
+		cfg.Address = spec.VaultAddress
+	}
+	client, err := vault.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &vaultTransitKeyProvider{
+		name:      spec.Name,
+		mountPath: mountPath,
+		keyName:   keyName,
+		client:    client,
+	}, nil
+}
+
+func splitVaultTransitID(id string) (mountPath, keyName string, err error) {
+	for i := len(id) - 1; i >= 0; i-- {
+		if id[i] == '/' {
+			return id[:i], id[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("vault transit key id %q must be \"<mount>/<key>\"", id)
+}
+
+func (p *vaultTransitKeyProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return "vaulttransit"
+}
+
+func (p *vaultTransitKeyProvider) Decrypt(ctx context.Context, dataKey []byte) ([]byte, error) {
+	secret, err := p.client.Logical().WriteWithContext(ctx,
+		fmt.Sprintf("%s/decrypt/%s", p.mountPath, p.keyName),
+		map[string]interface{}{"ciphertext": string(dataKey)})
+	if err != nil {
+		return nil, err
+	}
+	plaintext, ok := secret.Data["plaintext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("vault transit decrypt: missing plaintext in response")
+	}
+	return base64.StdEncoding.DecodeString(plaintext)
+}
+
+func (p *vaultTransitKeyProvider) Encrypt(ctx context.Context, dataKey []byte) ([]byte, error) {
+	secret, err := p.client.Logical().WriteWithContext(ctx,
+		fmt.Sprintf("%s/encrypt/%s", p.mountPath, p.keyName),
+		map[string]interface{}{"plaintext": base64.StdEncoding.EncodeToString(dataKey)})
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, ok := secret.Data["ciphertext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("vault transit encrypt: missing ciphertext in response")
+	}
+	return []byte(ciphertext), nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package keyservice
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// codecName is registered as a gRPC content-subtype so Decrypt's plain Go
+// structs can ride over gRPC without a protoc-generated proto.Message
+// implementation. Client calls opt into it with grpc.CallContentSubtype;
+// the codec is registered process-wide in init, so any grpc.Server in the
+// process, including one from NewServer, decodes such calls.
+const codecName = "kvjson"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+func (jsonCodec) Name() string { return codecName }
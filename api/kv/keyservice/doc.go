@@ -0,0 +1,16 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package keyservice contains the client/server stubs for the keyservice
+// gRPC API defined in keyservice.proto. This tree has no protoc toolchain
+// wired into its build, so keyservice.pb.go and keyservice_grpc.pb.go are
+// hand-written to the same message/service shape protoc-gen-go and
+// protoc-gen-go-grpc would produce, marshaled with the "kvjson" codec
+// (codec.go) instead of the protobuf wire format. There is no `go
+// generate` step for this package: if a real protoc toolchain is ever
+// wired in, regenerating from keyservice.proto will produce code that
+// marshals as actual protobuf, which is a breaking wire-format change for
+// anything already talking "kvjson" to a keyservice built from this
+// package -- coordinate that migration rather than running protoc
+// silently over these files.
+package keyservice
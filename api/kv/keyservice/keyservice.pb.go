@@ -0,0 +1,29 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Hand-written to the shape keyservice.proto describes (see doc.go for why
+// this isn't protoc-gen-go output). These types are marshaled with the
+// "kvjson" codec registered in codec.go rather than protobuf wire format,
+// so edit them by hand as needed -- there is no generator to reconcile
+// them with.
+
+package keyservice
+
+// Stanza is the wire form of an age recipient stanza, as parsed from an
+// armored blob's header (age-encryption.org/v1; Body is already
+// base64-decoded).
+type Stanza struct {
+	Type string   `json:"type"`
+	Args []string `json:"args"`
+	Body []byte   `json:"body"`
+}
+
+// DecryptRequest is the Decrypt RPC's request message.
+type DecryptRequest struct {
+	RecipientStanzas []*Stanza `json:"recipient_stanzas"`
+}
+
+// DecryptResponse is the Decrypt RPC's response message.
+type DecryptResponse struct {
+	FileKey []byte `json:"file_key"`
+}
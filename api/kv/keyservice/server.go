@@ -0,0 +1,23 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package keyservice
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func grpcUnimplemented(method string) error {
+	return status.Errorf(codes.Unimplemented, "method %s not implemented", method)
+}
+
+// NewServer returns a *grpc.Server configured to speak the "kvjson"
+// content-subtype this package's client uses, so a KeyServiceServer
+// implementation (e.g. one backed by an HSM or YubiKey) can be registered
+// with RegisterKeyServiceServer and served over the socket named in
+// types.KvPairSources.KeyServiceURIs / $KUSTOMIZE_KEYSERVICE.
+func NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	return grpc.NewServer(opts...)
+}
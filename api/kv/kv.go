@@ -6,6 +6,7 @@ package kv
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -36,25 +37,51 @@ type loader struct {
 
 	// Used to validate various k8s data fields.
 	validator ifc.Validator
+
+	// connCache holds the keyservice gRPC connections and ssh-agent socket
+	// opened by getAgeIdentities, reused across repeated Load calls (as
+	// Watch makes on every debounced reload) instead of leaking one per
+	// call. See Close.
+	connCache connCache
 }
 
 func NewLoader(ldr ifc.Loader, rootLdr ifc.Loader, v ifc.Validator) ifc.KvLoader {
 	return &loader{ldr: ldr, rootLdr: rootLdr, validator: v}
 }
 
+// Close releases the connections opened on this loader's behalf by
+// getAgeIdentities (keyservice gRPC connections, the ssh-agent socket).
+// Load opens and caches these too, so Close releases them after one-shot
+// Load calls as well; a short-lived process may skip it and let exit drop
+// them. Long-running users of Watch should call it once they stop watching.
+func (kvl *loader) Close() error {
+	return kvl.connCache.Close()
+}
+
 func (kvl *loader) Validator() ifc.Validator {
 	return kvl.validator
 }
 
 func (kvl *loader) Load(
 	args types.KvPairSources) (all []types.Pair, err error) {
-	ids, err := kvl.getAgeIdentities(args.AgeIdentitySources)
+	ids, err := kvl.getAgeIdentities(args.AgeIdentitySources, args.KeyServiceURIs)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(
 			"age identity source files: %v",
 			args.AgeIdentitySources))
 	}
 
+	kps, err := keyProvidersFromSpecs(args.KeyProviders)
+	if err != nil {
+		return nil, errors.Wrap(err, "key providers")
+	}
+	ids = append(ids, keyProviderIdentities(context.Background(), kps)...)
+
+	policy, err := newYAMLEncryptionPolicyFromArgs(args)
+	if err != nil {
+		return nil, errors.Wrap(err, "inline YAML encryption policy")
+	}
+
 	pairs, err := kvl.keyValuesFromEnvFiles(args.EnvSources, ids)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(
@@ -63,14 +90,14 @@ func (kvl *loader) Load(
 	}
 	all = append(all, pairs...)
 
-	pairs, err = keyValuesFromLiteralSources(args.LiteralSources, ids)
+	pairs, err = keyValuesFromLiteralSources(args.LiteralSources, ids, policy)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(
 			"literal sources %v", args.LiteralSources))
 	}
 	all = append(all, pairs...)
 
-	pairs, err = kvl.keyValuesFromFileSources(args.FileSources, ids)
+	pairs, err = kvl.keyValuesFromFileSources(args.FileSources, ids, policy)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf(
 			"file sources: %v", args.FileSources))
@@ -78,8 +105,19 @@ func (kvl *loader) Load(
 	return append(all, pairs...), nil
 }
 
-func (kvl *loader) getAgeIdentities(sources []string) ([]age.Identity, error) {
-	var ids []age.Identity
+func (kvl *loader) getAgeIdentities(sources []string, keyServiceURIs []string) ([]age.Identity, error) {
+	ksIDs, err := kvl.connCache.keyServiceIdentities(context.Background(), keyServiceURIs)
+	if err != nil {
+		return nil, err
+	}
+	ids := ksIDs
+
+	agentIDs, err := kvl.connCache.sshAgentIdentities()
+	if err != nil {
+		return nil, err
+	}
+	ids = append(ids, agentIDs...)
+
 	if len(sources) > 0 {
 		for _, path := range sources {
 			path, err := filepath.Abs(path)
@@ -119,7 +157,8 @@ func (kvl *loader) getAgeIdentities(sources []string) ([]age.Identity, error) {
 	return ids, nil
 }
 
-func keyValuesFromLiteralSources(sources []string, ids []age.Identity) ([]types.Pair, error) {
+func keyValuesFromLiteralSources(
+	sources []string, ids []age.Identity, policy yamlEncryptionPolicy) ([]types.Pair, error) {
 	var kvs []types.Pair
 	for _, s := range sources {
 		k, v, err := parseLiteralSource(s)
@@ -130,7 +169,7 @@ func keyValuesFromLiteralSources(sources []string, ids []age.Identity) ([]types.
 			k = strings.TrimRight(k, ".age")
 			content := []byte(v)
 			if strings.HasSuffix(k, ".yaml") || strings.HasSuffix(k, ".yml") {
-				content, err = decryptInlineYAMLWithAge(content, ids)
+				content, err = decryptInlineYAMLWithAge(content, ids, policy)
 			} else {
 				content, err = decryptValueWithAge(content, ids)
 			}
@@ -144,7 +183,8 @@ func keyValuesFromLiteralSources(sources []string, ids []age.Identity) ([]types.
 	return kvs, nil
 }
 
-func (kvl *loader) keyValuesFromFileSources(sources []string, ids []age.Identity) ([]types.Pair, error) {
+func (kvl *loader) keyValuesFromFileSources(
+	sources []string, ids []age.Identity, policy yamlEncryptionPolicy) ([]types.Pair, error) {
 	var kvs []types.Pair
 	for _, s := range sources {
 		k, fPath, err := parseFileSource(s)
@@ -162,7 +202,7 @@ func (kvl *loader) keyValuesFromFileSources(sources []string, ids []age.Identity
 				!bytes.HasPrefix(content, []byte(armor.Header)) {
 				// If key has .yaml or .yml extension and has no age armor header
 				// then we try inline decrypting of the file.
-				content, err = decryptInlineYAMLWithAge(content, ids)
+				content, err = decryptInlineYAMLWithAge(content, ids, policy)
 			} else {
 				content, err = decryptValueWithAge(content, ids)
 			}
@@ -264,10 +304,10 @@ func (kvl *loader) keyValuesFromLine(line []byte, currentLine int) (types.Pair,
 
 // ParseFileSource parses the source given.
 //
-//  Acceptable formats include:
-//   1.  source-path: the basename will become the key name
-//   2.  source-name=source-path: the source-name will become the key name and
-//       source-path is the path to the key file.
+//	Acceptable formats include:
+//	 1.  source-path: the basename will become the key name
+//	 2.  source-name=source-path: the source-name will become the key name and
+//	     source-path is the path to the key file.
 //
 // Key names cannot include '='.
 func parseFileSource(source string) (keyName, filePath string, err error) {
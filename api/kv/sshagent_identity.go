@@ -0,0 +1,176 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+
+	"filippo.io/age"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// sshAgentEnvVar is the escape hatch for environments where talking to
+// ssh-agent is undesirable or unsafe (e.g. a forwarded agent a CI job
+// doesn't trust). Set to "off" to skip agent identities entirely.
+const sshAgentEnvVar = "KUSTOMIZE_AGE_SSH_AGENT"
+
+// ageUnwrapExtension is a non-standard ssh-agent extension
+// (golang.org/x/crypto/ssh/agent.ExtendedAgent.Extension) this package
+// invented to ask the agent to unwrap an age ssh-ed25519/ssh-rsa
+// recipient stanza and return the file key, without ever exporting the
+// private key. No agent shipping today -- not OpenSSH's ssh-agent,
+// gpg-agent, nor yubikey-agent -- implements it: stock ssh-agent only
+// signs, it has no ECDH or RSA-decrypt operation, and there is no
+// existing companion process that speaks this extension either. Every
+// real agent will return agent.ErrExtensionUnsupported for every stanza,
+// same as an identity that doesn't match, and agentIdentity falls through
+// to the disk-file identities in that case -- so until a bespoke agent
+// (or a wrapper in front of a stock one) implementing this extension
+// exists, this path is inert and behavior is unchanged from before
+// agentIdentity was added. What the extension asks for, for whoever
+// builds that companion agent: for ssh-ed25519 the birational
+// Ed25519->X25519 conversion plus the HKDF "tweak" age specifies, then
+// the X25519 exchange; for ssh-rsa, RSA-OAEP.
+const ageUnwrapExtension = "age-ssh-unwrap@kustomize.sigs.k8s.io"
+
+// agentIdentity implements age.Identity for a single public key held by a
+// running ssh-agent (or a hardware key exposed through agent forwarding,
+// e.g. yubikey-agent). It never reads private key material itself; every
+// operation that needs the private key is delegated to the agent.
+type agentIdentity struct {
+	agent agent.ExtendedAgent
+	key   ssh.PublicKey
+	tag   []byte // sha256(ssh wire-format public key)[:4], as age's ssh recipient stanzas tag themselves
+}
+
+// sshAgentIdentities connects to $SSH_AUTH_SOCK and returns one
+// age.Identity per ssh-rsa/ssh-ed25519 key the agent holds, ahead of any
+// disk-file fallback. It returns (nil, nil) -- not an error -- when
+// KUSTOMIZE_AGE_SSH_AGENT=off or SSH_AUTH_SOCK isn't set, so the caller can
+// fall through to file-based identities. The connection is cached on c and
+// reused by later calls, so a long-running caller (Watch) doesn't open a
+// new socket per reload; call c.Close when done with them.
+func (c *connCache) sshAgentIdentities() ([]age.Identity, error) {
+	if os.Getenv(sshAgentEnvVar) == "off" {
+		return nil, nil
+	}
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, nil
+	}
+	extAgent, err := c.dialSSHAgent(sock)
+	if err != nil {
+		return nil, nil
+	}
+	keys, err := extAgent.List()
+	if err != nil {
+		return nil, err
+	}
+	var ids []age.Identity
+	for _, k := range keys {
+		switch k.Type() {
+		case ssh.KeyAlgoED25519, ssh.KeyAlgoRSA:
+			tag := sha256.Sum256(k.Marshal())
+			ids = append(ids, &agentIdentity{agent: extAgent, key: k, tag: tag[:4]})
+		}
+	}
+	return ids, nil
+}
+
+// dialSSHAgent returns the cached connection to sock, dialing (and
+// caching) it on first use.
+func (c *connCache) dialSSHAgent(sock string) (agent.ExtendedAgent, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sshAgentConn != nil {
+		return c.sshAgentConn.agent, nil
+	}
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, err
+	}
+	extAgent, ok := agent.NewClient(conn).(agent.ExtendedAgent)
+	if !ok {
+		_ = conn.Close()
+		return nil, fmt.Errorf("agent at %s does not support extensions", sock)
+	}
+	c.sshAgentConn = &cachedSSHAgentConn{conn: conn, agent: extAgent}
+	return extAgent, nil
+}
+
+// cachedSSHAgentConn pairs the dialed socket with the agent client wrapping
+// it, so connCache.Close can close the former without the agent package
+// exposing a Close of its own.
+type cachedSSHAgentConn struct {
+	conn  net.Conn
+	agent agent.ExtendedAgent
+}
+
+func (a *agentIdentity) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if !a.matches(s) {
+			continue
+		}
+		fileKey, err := a.unwrap(s)
+		if err != nil {
+			continue
+		}
+		return fileKey, nil
+	}
+	return nil, age.ErrIncorrectIdentity
+}
+
+// matches reports whether s is an ssh-ed25519/ssh-rsa stanza tagged for
+// this identity's public key, mirroring the stanza shape
+// filippo.io/age/agessh.Recipient.Wrap produces: Type is "ssh-ed25519" or
+// "ssh-rsa" and Args[0] is the base64 tag this key's Wrap call would have
+// used.
+func (a *agentIdentity) matches(s *age.Stanza) bool {
+	typeMatches := (s.Type == "ssh-ed25519" && a.key.Type() == ssh.KeyAlgoED25519) ||
+		(s.Type == "ssh-rsa" && a.key.Type() == ssh.KeyAlgoRSA)
+	if !typeMatches {
+		return false
+	}
+	if len(s.Args) == 0 {
+		return false
+	}
+	tag, err := base64.RawStdEncoding.DecodeString(s.Args[0])
+	if err != nil {
+		return false
+	}
+	return string(tag) == string(a.tag)
+}
+
+// unwrap delegates the actual ssh-ed25519/ssh-rsa unwrap -- the tweaked
+// X25519 exchange or RSA-OAEP decrypt that filippo.io/age/agessh.Identity
+// would normally do against a locally-held private key -- to the agent
+// extension, since stock ssh-agent exposes no such operation. The payload
+// is the stanza itself (type, args, body); the extension returns the
+// unwrapped file key.
+func (a *agentIdentity) unwrap(s *age.Stanza) ([]byte, error) {
+	payload, err := json.Marshal(ageUnwrapRequest{Type: s.Type, Args: s.Args, Body: s.Body})
+	if err != nil {
+		return nil, err
+	}
+	out, err := a.agent.Extension(ageUnwrapExtension, payload)
+	if err != nil {
+		return nil, fmt.Errorf("ssh-agent age unwrap: %w", err)
+	}
+	return out, nil
+}
+
+// ageUnwrapRequest is the JSON payload sent to ageUnwrapExtension.
+type ageUnwrapRequest struct {
+	Type string   `json:"type"`
+	Args []string `json:"args"`
+	Body []byte   `json:"body"`
+}
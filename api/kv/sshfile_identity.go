@@ -0,0 +1,48 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// parseSSHIdentity parses the unencrypted OpenSSH private key in content
+// (as found at e.g. $HOME/.ssh/id_ed25519) into the age identity it
+// implies, for use with age's ssh-rsa/ssh-ed25519 recipient stanzas.
+// path is only used for error messages.
+func parseSSHIdentity(path string, content []byte) ([]age.Identity, error) {
+	raw, err := ssh.ParseRawPrivateKey(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	switch key := raw.(type) {
+	case *ed25519.PrivateKey:
+		id, err := agessh.NewEd25519Identity(*key)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Identity{id}, nil
+	case ed25519.PrivateKey:
+		id, err := agessh.NewEd25519Identity(key)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Identity{id}, nil
+	case *rsa.PrivateKey:
+		id, err := agessh.NewRSAIdentity(key)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Identity{id}, nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported ssh key type %T", path, raw)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// watchDebounce coalesces the burst of events a single `kustomize edit
+// encrypt-secret` or key rotation tends to produce (write, chmod, rename...)
+// into one re-Load.
+const watchDebounce = 100 * time.Millisecond
+
+// Watch watches every EnvSources, FileSources, and AgeIdentitySources path
+// in args (plus $HOME/.ssh/id_* when ssh-derived identities are in play)
+// for changes, debounces them, and calls onChange with a freshly Load-ed
+// set of pairs -- or the error Load returned -- each time something
+// settles. It returns once the watch is established; ctx cancellation
+// stops the watch goroutine. The synchronous Load path is unaffected.
+func (kvl *loader) Watch(
+	ctx context.Context, args types.KvPairSources, onChange func([]types.Pair, error)) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	paths := watchPaths(args)
+	for dir := range watchDirs(paths) {
+		if err := w.Add(dir); err != nil {
+			_ = w.Close()
+			return err
+		}
+	}
+
+	go kvl.watchLoop(ctx, w, paths, args, onChange)
+	return nil
+}
+
+func (kvl *loader) watchLoop(
+	ctx context.Context, w *fsnotify.Watcher, paths map[string]bool, args types.KvPairSources,
+	onChange func([]types.Pair, error)) {
+	defer w.Close()
+
+	var debounce *time.Timer
+	reload := func() {
+		pairs, err := kvl.Load(args)
+		onChange(pairs, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if debounce != nil {
+				debounce.Stop()
+			}
+			return
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if !paths[ev.Name] {
+				// A directory we watch so we can see recreated/rotated
+				// files may also deliver events for sibling files we
+				// don't care about; ignore those.
+				continue
+			}
+			if debounce != nil {
+				debounce.Stop()
+			}
+			debounce = time.AfterFunc(watchDebounce, reload)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			onChange(nil, err)
+		}
+	}
+}
+
+// watchDirs returns the deduplicated set of parent directories of paths. A
+// watch is placed on the containing directory rather than the file itself:
+// fsnotify's watch is bound to the inode it resolved at Add time, so a
+// watch on the file path alone goes dead the moment the file is replaced
+// via the write-temp-then-rename pattern key rotation and atomic rewrites
+// normally use. Watching the directory survives that, at the cost of also
+// delivering events for unrelated siblings, which watchLoop filters by
+// name.
+func watchDirs(paths map[string]bool) map[string]bool {
+	dirs := map[string]bool{}
+	for p := range paths {
+		dirs[filepath.Dir(p)] = true
+	}
+	return dirs
+}
+
+// watchPaths lists every file kustomize reads to build args, deduplicated
+// and filtered to paths that currently exist (a not-yet-created identity
+// file simply can't rotate until it exists, and its parent directory may
+// not exist either).
+func watchPaths(args types.KvPairSources) map[string]bool {
+	seen := map[string]bool{}
+	add := func(p string) {
+		if p == "" {
+			return
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			abs = p
+		}
+		if _, err := os.Stat(abs); err != nil {
+			return
+		}
+		seen[abs] = true
+	}
+
+	for _, p := range args.EnvSources {
+		add(p)
+	}
+	for _, s := range args.FileSources {
+		_, p, err := parseFileSource(s)
+		if err != nil {
+			continue
+		}
+		add(p)
+	}
+	for _, p := range args.AgeIdentitySources {
+		add(p)
+	}
+
+	if matches, err := filepath.Glob(os.ExpandEnv("$HOME/.ssh/id_*")); err == nil {
+		for _, m := range matches {
+			add(m)
+		}
+	}
+	return seen
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// diskLoader reads straight off the local filesystem; it's the minimal
+// ifc.Loader a test can construct without pulling in the real loader
+// implementation kustomize wires up in production.
+type diskLoader struct{}
+
+func (diskLoader) Load(path string) ([]byte, error) { return os.ReadFile(path) }
+
+// noopValidator accepts every key; env-var-name validation isn't what
+// this test is exercising.
+type noopValidator struct{}
+
+func (noopValidator) IsEnvVarName(string) error { return nil }
+
+// TestWatchDirsWatchesParentNotFile guards against regressing to watching
+// leaf file paths directly: fsnotify's watch is bound to the inode it
+// resolved at Add time, so a watch on the file itself goes dead the moment
+// the file is replaced via the usual write-temp-then-rename pattern key
+// rotation and atomic rewrites use. watchDirs must return the containing
+// directory instead.
+func TestWatchDirsWatchesParentNotFile(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a")
+	b := filepath.Join(dir, "sub", "b")
+
+	dirs := watchDirs(map[string]bool{a: true, b: true})
+
+	if dirs[a] {
+		t.Fatalf("watchDirs returned the file path %q itself, want its parent directory", a)
+	}
+	if !dirs[dir] {
+		t.Fatalf("watchDirs missing %q, got %v", dir, dirs)
+	}
+	if !dirs[filepath.Join(dir, "sub")] {
+		t.Fatalf("watchDirs missing %q, got %v", filepath.Join(dir, "sub"), dirs)
+	}
+}
+
+// TestWatchPathsSkipsMissingFiles confirms a not-yet-created identity file
+// is left out (its directory can't be watched into existence either) while
+// existing sources are included as absolute paths.
+func TestWatchPathsSkipsMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "identity.txt")
+	if err := os.WriteFile(existing, []byte("AGE-SECRET-KEY-..."), 0o600); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	missing := filepath.Join(dir, "does-not-exist.txt")
+
+	paths := watchPaths(types.KvPairSources{
+		AgeIdentitySources: []string{existing, missing},
+	})
+
+	if !paths[existing] {
+		t.Fatalf("watchPaths missing existing file %q, got %v", existing, paths)
+	}
+	if paths[missing] {
+		t.Fatalf("watchPaths should have skipped missing file %q, got %v", missing, paths)
+	}
+}
+
+// TestLoaderWatchReloadsOnAtomicReplace drives loader.Watch end to end
+// through the ifc.KvLoader interface: it rewrites a watched env file the
+// way key rotation/atomic edits actually happen (write a temp file, then
+// rename it into place) and asserts onChange eventually fires with the
+// freshly Load-ed contents, confirming the directory-watch fix survives a
+// real replace. Only the first onChange call is checked.
+func TestLoaderWatchReloadsOnAtomicReplace(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envPath, []byte("FOO=bar\n"), 0o600); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	kvl := NewLoader(diskLoader{}, diskLoader{}, noopValidator{})
+	defer kvl.Close()
+
+	args := types.KvPairSources{EnvSources: []string{envPath}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan []types.Pair, 4)
+	errs := make(chan error, 4)
+	if err := kvl.Watch(ctx, args, func(pairs []types.Pair, err error) {
+		if err != nil {
+			errs <- err
+			return
+		}
+		changes <- pairs
+	}); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	// Give the watcher a moment to actually register with the OS before
+	// the replace, then rewrite via write-temp-then-rename rather than
+	// truncating envPath in place.
+	time.Sleep(50 * time.Millisecond)
+	tmp := envPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte("FOO=baz\n"), 0o600); err != nil {
+		t.Fatalf("writing replacement: %v", err)
+	}
+	if err := os.Rename(tmp, envPath); err != nil {
+		t.Fatalf("renaming replacement into place: %v", err)
+	}
+
+	select {
+	case pairs := <-changes:
+		if len(pairs) != 1 || pairs[0].Key != "FOO" || pairs[0].Value != "baz" {
+			t.Fatalf("got %+v, want a single FOO=baz pair", pairs)
+		}
+	case err := <-errs:
+		t.Fatalf("onChange reported an error: %v", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for onChange after the atomic replace")
+	}
+}
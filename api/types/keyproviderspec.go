@@ -0,0 +1,41 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// KeyProviderSpec selects and configures one external key management
+// backend (see kv.KeyProvider) used to wrap/unwrap the per-file data key
+// for an age-armored KV source, as an alternative to a raw age identity.
+type KeyProviderSpec struct {
+	// Name identifies this provider instance, e.g. "prod-kms". It is
+	// recorded as the stanza type in the data this provider wraps, so a
+	// later Load knows which configured provider to ask.
+	Name string
+
+	// Type selects the provider implementation: "age", "awskms",
+	// "gcpkms", "azurekeyvault", "vaulttransit", or "pgp".
+	Type string
+
+	// ID is the provider-specific key identifier: an age identity
+	// string (type "age"), a KMS key ARN or resource name (type
+	// "awskms"/"gcpkms"), an Azure Key Vault key name (type
+	// "azurekeyvault"), a Vault Transit "mount/key" path (type
+	// "vaulttransit"), or a path to an armored PGP keyring file (type
+	// "pgp").
+	ID string
+
+	// Region overrides the AWS KMS client's default region (type
+	// "awskms" only).
+	Region string
+
+	// VaultURL is the Azure Key Vault URL (type "azurekeyvault" only).
+	VaultURL string
+
+	// VaultAddress overrides the Vault client's default address (type
+	// "vaulttransit" only); falls back to $VAULT_ADDR when empty.
+	VaultAddress string
+
+	// Passphrase optionally decrypts a passphrase-protected PGP private
+	// key (type "pgp" only).
+	Passphrase string
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Pair is a single resolved key/value pair, the result of loading one
+// entry from a KvPairSources literal, file, or env source.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// KvPairSources holds the unresolved --from-literal/--from-file/
+// --from-env-file style inputs used to build a secretGenerator or
+// configMapGenerator entry, plus the age-encryption options layered on
+// top of them for sources that are wholly or partially ciphertext. A
+// kv.Loader resolves these into []Pair.
+type KvPairSources struct {
+	// LiteralSources is a list of "key=value" literal pairs.
+	LiteralSources []string
+
+	// FileSources is a list of file sources, either "path" (the
+	// basename becomes the key) or "key=path".
+	FileSources []string
+
+	// EnvSources is a list of paths to env files (KEY=VALUE per line).
+	EnvSources []string
+
+	// AgeIdentitySources lists paths to age identity files used to
+	// decrypt any .age-suffixed source above. They are tried after any
+	// keyservice and ssh-agent identities and ahead of the
+	// $HOME/.ssh/id_* fallback.
+	AgeIdentitySources []string
+
+	// AgeRecipients lists the recipients age-armored sources are
+	// encrypted to: age1... strings, paths to .pub files, or
+	// https://github.com/<user>.keys URLs. See kv.AgeRecipientsFromSpecs,
+	// which resolves this list.
+	AgeRecipients []string
+
+	// KeyServiceURIs lists keyservice gRPC/UDS endpoints to try ahead of
+	// local identities when decrypting, falling back to
+	// $KUSTOMIZE_KEYSERVICE when empty.
+	KeyServiceURIs []string
+
+	// KeyProviders configures external key management backends (KMS,
+	// Vault Transit, PGP, ...) whose wrapped data keys should be tried
+	// alongside ordinary age recipient stanzas, on both the encrypt and
+	// decrypt side.
+	KeyProviders []KeyProviderSpec
+
+	// UnencryptedKeySuffix overrides the default "_unencrypted" mapping
+	// key suffix that opts a YAML subtree out of inline age encryption.
+	UnencryptedKeySuffix string
+
+	// EncryptedKeyRegex, when set, limits inline YAML age encryption to
+	// scalars nested under a mapping key matching this regular
+	// expression. At most one of EncryptedKeyRegex/EncryptedKeySuffix
+	// should be set.
+	EncryptedKeyRegex string
+
+	// EncryptedKeySuffix is shorthand for an EncryptedKeyRegex matching
+	// keys ending in this literal suffix.
+	EncryptedKeySuffix string
+}
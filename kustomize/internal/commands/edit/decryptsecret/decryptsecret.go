@@ -0,0 +1,71 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package decryptsecret
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+type decryptSecretOptions struct {
+	fileSources        []string
+	ageIdentitySources []string
+	out                string
+}
+
+// NewCmdDecryptSecret returns an instance of 'edit decrypt-secret'
+// subcommand, the inverse of 'edit encrypt-secret'.
+func NewCmdDecryptSecret(ldr ifc.KvLoader) *cobra.Command {
+	var o decryptSecretOptions
+	cmd := &cobra.Command{
+		Use:   "decrypt-secret",
+		Short: "Decrypt a previously age-encrypted secretGenerator value",
+		Long: `Decrypts an armored .age file using the identities configured for
+ldr, printing the plaintext so it can be inspected or re-encrypted, e.g.
+
+  kustomize edit decrypt-secret --from-file=password.txt.age \
+    --age-identity=$HOME/.config/age/keys.txt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd, ldr)
+		},
+	}
+	cmd.Flags().StringArrayVar(&o.fileSources, "from-file", nil,
+		"path to a previously encrypted .age file")
+	cmd.Flags().StringArrayVar(&o.ageIdentitySources, "age-identity", nil,
+		"path to an age identity file")
+	cmd.Flags().StringVarP(&o.out, "output", "o", "",
+		"write the last decrypted plaintext here instead of stdout")
+	return cmd
+}
+
+func (o *decryptSecretOptions) Run(cmd *cobra.Command, ldr ifc.KvLoader) error {
+	for _, path := range o.fileSources {
+		pairs, err := ldr.Load(types.KvPairSources{
+			FileSources:        []string{"decryptsecret=" + path},
+			AgeIdentitySources: o.ageIdentitySources,
+		})
+		if err != nil {
+			return err
+		}
+		for _, p := range pairs {
+			if err := o.emit(cmd, p.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (o *decryptSecretOptions) emit(cmd *cobra.Command, plaintext string) error {
+	if o.out != "" {
+		return ioutil.WriteFile(o.out, []byte(plaintext), 0o600)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), plaintext)
+	return nil
+}
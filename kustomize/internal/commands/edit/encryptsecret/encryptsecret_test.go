@@ -0,0 +1,80 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package encryptsecret
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+
+	"github.com/spf13/cobra"
+)
+
+// TestParseKeyProviderSpecs guards the name:type:id --key-provider flag
+// format against regressions.
+func TestParseKeyProviderSpecs(t *testing.T) {
+	specs, err := parseKeyProviderSpecs([]string{"prod-kms:awskms:arn:aws:kms:us-east-1:123:key/abc"})
+	if err != nil {
+		t.Fatalf("parseKeyProviderSpecs: %v", err)
+	}
+	if len(specs) != 1 {
+		t.Fatalf("got %d specs, want 1", len(specs))
+	}
+	got := specs[0]
+	if got.Name != "prod-kms" || got.Type != "awskms" || got.ID != "arn:aws:kms:us-east-1:123:key/abc" {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+// TestParseKeyProviderSpecsRejectsMalformed confirms a spec missing the
+// name or type segment is rejected instead of silently producing a
+// KeyProviderSpec with an empty Name or Type.
+func TestParseKeyProviderSpecsRejectsMalformed(t *testing.T) {
+	if _, err := parseKeyProviderSpecs([]string{"just-a-name"}); err == nil {
+		t.Fatal("expected an error for a spec missing type:id, got nil")
+	}
+}
+
+// TestRunFromFileKeepsFullBasename guards against regressing to the old
+// TrimSuffix(base, ".txt") special case: --from-file=password.txt must
+// produce key password.txt.age (matching decrypt-secret's own doc
+// example), the same as any other extension.
+func TestRunFromFileKeepsFullBasename(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("generating test identity: %v", err)
+	}
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "password.txt")
+	if err := os.WriteFile(srcPath, []byte("hunter2"), 0o600); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+	recipientPath := filepath.Join(dir, "recipient.pub")
+	if err := os.WriteFile(recipientPath, []byte(id.Recipient().String()+"\n"), 0o600); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	o := &encryptSecretOptions{
+		fileSources: []string{srcPath},
+		recipients:  []string{recipientPath},
+	}
+	cmd := &cobra.Command{}
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	if err := o.Run(cmd); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	key, _, ok := strings.Cut(strings.TrimSpace(out.String()), "=")
+	if !ok {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+	if key != "password.txt.age" {
+		t.Fatalf("got key %q, want %q", key, "password.txt.age")
+	}
+}